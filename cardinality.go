@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// CardinalityTier is a rough estimate of how many distinct values a label
+// (or a histogram's bucket set) is likely to contribute to a metric's series
+// count.
+type CardinalityTier string
+
+const (
+	CardinalityLow       CardinalityTier = "low"
+	CardinalityMedium    CardinalityTier = "medium"
+	CardinalityHigh      CardinalityTier = "high"
+	CardinalityUnbounded CardinalityTier = "unbounded"
+)
+
+// cardinalityTierRank orders tiers from least to most concerning, and also
+// doubles as the per-label multiplier used to estimate a metric's series
+// budget: each added label roughly multiplies a metric's existing series
+// count by this many distinct values.
+var cardinalityTierRank = map[CardinalityTier]int{
+	CardinalityLow:       1,
+	CardinalityMedium:    10,
+	CardinalityHigh:      100,
+	CardinalityUnbounded: 10000,
+}
+
+// defaultCardinalityRules maps known-risky label names to their estimated
+// cardinality tier. It mirrors the label names Kubernetes' own
+// instrumentation guidelines call out as cardinality hazards.
+var defaultCardinalityRules = map[string]CardinalityTier{
+	"pod":              CardinalityHigh,
+	"node":             CardinalityHigh,
+	"namespace":        CardinalityMedium,
+	"uid":              CardinalityUnbounded,
+	"container_id":     CardinalityUnbounded,
+	"resource_version": CardinalityUnbounded,
+}
+
+var (
+	ipShapedLabel   = regexp.MustCompile(`(?i)(^|_)(ip|address|addr)$`)
+	hashShapedLabel = regexp.MustCompile(`(?i)(hash|digest|checksum|fingerprint)`)
+)
+
+// defaultHistogramBucketThreshold is the number of buckets above which an
+// Added histogram is flagged, absent an explicit --histogram-bucket-threshold.
+const defaultHistogramBucketThreshold = 10
+
+// loadCardinalityRules reads label-name -> tier overrides from a YAML file
+// (a flat map, e.g. `pod_template_hash: high`) and merges them over
+// defaultCardinalityRules, letting operators tune the heuristic to their own
+// label-naming conventions without patching the tool.
+func loadCardinalityRules(path string) (map[string]CardinalityTier, error) {
+	rules := make(map[string]CardinalityTier, len(defaultCardinalityRules))
+	for label, tier := range defaultCardinalityRules {
+		rules[label] = tier
+	}
+
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides map[string]CardinalityTier
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parsing cardinality rules %s: %w", path, err)
+	}
+	for label, tier := range overrides {
+		rules[strings.ToLower(label)] = tier
+	}
+
+	return rules, nil
+}
+
+// classifyLabelCardinality estimates the cardinality tier of a single label
+// name against the configured heuristic rules, falling back to shape-based
+// detection (IP-shaped, hash-shaped) and finally CardinalityLow.
+func classifyLabelCardinality(label string, rules map[string]CardinalityTier) CardinalityTier {
+	lower := strings.ToLower(label)
+
+	if tier, ok := rules[lower]; ok {
+		return tier
+	}
+	if ipShapedLabel.MatchString(lower) {
+		return CardinalityHigh
+	}
+	if hashShapedLabel.MatchString(lower) {
+		return CardinalityUnbounded
+	}
+
+	return CardinalityLow
+}
+
+// CardinalityWarning flags one metric diff likely to cause a cardinality
+// explosion, with an estimated "series budget" multiplier explaining why.
+type CardinalityWarning struct {
+	Key             string
+	Tier            CardinalityTier
+	Reasons         []string
+	EstimatedSeries int
+}
+
+// analyzeCardinality scans diffs for label additions and new histograms
+// likely to blow up series counts, using rules to size each label and
+// bucketThreshold to flag unusually wide histograms.
+func analyzeCardinality(diffs []MetricDiff, rules map[string]CardinalityTier, bucketThreshold int) []CardinalityWarning {
+	var warnings []CardinalityWarning
+
+	for _, diff := range diffs {
+		switch diff.Type {
+		case Updated:
+			added, _ := diffLabelSlices(diff.OldMetric.Labels, diff.NewMetric.Labels)
+			if len(added) == 0 {
+				continue
+			}
+
+			var reasons []string
+			tier := CardinalityLow
+			seriesMultiplier := 1
+			for _, label := range added {
+				labelTier := classifyLabelCardinality(label, rules)
+				reasons = append(reasons, fmt.Sprintf("Added label `%s` estimated as %s-cardinality.", label, labelTier))
+				seriesMultiplier *= cardinalityTierRank[labelTier]
+				if cardinalityTierRank[labelTier] > cardinalityTierRank[tier] {
+					tier = labelTier
+				}
+			}
+
+			if tier == CardinalityLow {
+				continue
+			}
+
+			warnings = append(warnings, CardinalityWarning{
+				Key:             diff.Key,
+				Tier:            tier,
+				Reasons:         reasons,
+				EstimatedSeries: seriesMultiplier,
+			})
+
+		case Added:
+			if diff.NewMetric.Type != "histogram" || len(diff.NewMetric.Buckets) <= bucketThreshold {
+				continue
+			}
+
+			labelMultiplier := 1
+			tier := CardinalityLow
+			for _, label := range diff.NewMetric.Labels {
+				labelTier := classifyLabelCardinality(label, rules)
+				labelMultiplier *= cardinalityTierRank[labelTier]
+				if cardinalityTierRank[labelTier] > cardinalityTierRank[tier] {
+					tier = labelTier
+				}
+			}
+
+			warnings = append(warnings, CardinalityWarning{
+				Key:  diff.Key,
+				Tier: tier,
+				Reasons: []string{
+					fmt.Sprintf("New histogram has %d buckets (threshold %d).", len(diff.NewMetric.Buckets), bucketThreshold),
+				},
+				EstimatedSeries: (len(diff.NewMetric.Buckets) + 1) * labelMultiplier,
+			})
+		}
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Key < warnings[j].Key
+	})
+
+	return warnings
+}
+
+// printCardinalityWarnings renders a "⚠️ Cardinality Warnings" Markdown
+// section for the warnings found in one version pair's diffs.
+func printCardinalityWarnings(warnings []CardinalityWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	fmt.Println("## ⚠️ Cardinality Warnings")
+	fmt.Println()
+	fmt.Println("| Metric Name | Tier | Estimated Series Budget | Reasons |")
+	fmt.Println("|-------------|------|--------------------------|---------|")
+
+	for _, warning := range warnings {
+		reasons := strings.ReplaceAll(strings.Join(warning.Reasons, " <br> "), "|", "\\|")
+		fmt.Printf("| %s | %s | %d | %s |\n", warning.Key, warning.Tier, warning.EstimatedSeries, reasons)
+	}
+	fmt.Println()
+}
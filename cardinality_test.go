@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCardinalityRulesLowercasesOverrideKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(path, []byte("Namespace: unbounded\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := loadCardinalityRules(path)
+	if err != nil {
+		t.Fatalf("loadCardinalityRules: %v", err)
+	}
+
+	if got := classifyLabelCardinality("namespace", rules); got != CardinalityUnbounded {
+		t.Errorf("classifyLabelCardinality(namespace) = %s, want %s", got, CardinalityUnbounded)
+	}
+	if got := classifyLabelCardinality("Namespace", rules); got != CardinalityUnbounded {
+		t.Errorf("classifyLabelCardinality(Namespace) = %s, want %s", got, CardinalityUnbounded)
+	}
+}
+
+func TestLoadCardinalityRulesNoOverrides(t *testing.T) {
+	rules, err := loadCardinalityRules("")
+	if err != nil {
+		t.Fatalf("loadCardinalityRules: %v", err)
+	}
+
+	if got := classifyLabelCardinality("pod", rules); got != CardinalityHigh {
+		t.Errorf("classifyLabelCardinality(pod) = %s, want %s", got, CardinalityHigh)
+	}
+}
+
+func TestClassifyLabelCardinality(t *testing.T) {
+	rules := map[string]CardinalityTier{"pod": CardinalityHigh}
+
+	tests := []struct {
+		label string
+		want  CardinalityTier
+	}{
+		{"pod", CardinalityHigh},
+		{"POD", CardinalityHigh},
+		{"client_ip", CardinalityHigh},
+		{"request_hash", CardinalityUnbounded},
+		{"verb", CardinalityLow},
+	}
+
+	for _, tt := range tests {
+		if got := classifyLabelCardinality(tt.label, rules); got != tt.want {
+			t.Errorf("classifyLabelCardinality(%q) = %s, want %s", tt.label, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// expositionAcceptHeader requests OpenMetrics 1.0.0 first and falls back to
+// the classic Prometheus text format 0.0.4, matching what a kubelet or
+// apiserver /metrics endpoint actually serves.
+const expositionAcceptHeader = `application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.9`
+
+// loadFromExposition reads a Prometheus exposition endpoint — a live
+// http(s):// URL, a file:// URL, or "-" for stdin — and synthesizes a
+// map[string]Metric the same way loadMetrics does from a YAML dump. This
+// lets callers diff a documented metrics-<version>.yaml against what a
+// running component is actually exposing.
+func loadFromExposition(source string) (map[string]Metric, error) {
+	r, format, err := openExposition(source)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	decoder := expfmt.NewDecoder(r, format)
+
+	metricMap := make(map[string]Metric)
+	for {
+		var family dto.MetricFamily
+		if err := decoder.Decode(&family); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decoding exposition from %s: %w", source, err)
+		}
+
+		metric := metricFromFamily(&family)
+		metricMap[metricKey(metric)] = metric
+	}
+
+	return metricMap, nil
+}
+
+// isExpositionSource reports whether source names a live Prometheus
+// exposition endpoint (or stdin) rather than a YAML metrics dump.
+func isExpositionSource(source string) bool {
+	if source == "-" {
+		return true
+	}
+	for _, scheme := range []string{"http://", "https://", "file://"} {
+		if strings.HasPrefix(source, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadMetricsFromSource loads a map[string]Metric from either a YAML dump
+// (loadMetrics) or a live exposition source (loadFromExposition), chosen by
+// isExpositionSource.
+func loadMetricsFromSource(source string) (map[string]Metric, error) {
+	if isExpositionSource(source) {
+		return loadFromExposition(source)
+	}
+	return loadMetrics(source)
+}
+
+func openExposition(source string) (io.ReadCloser, expfmt.Format, error) {
+	if source == "-" {
+		return io.NopCloser(os.Stdin), expfmt.NewFormat(expfmt.TypeTextPlain), nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, "", err
+		}
+		return f, expfmt.NewFormat(expfmt.TypeTextPlain), nil
+	}
+
+	switch u.Scheme {
+	case "file":
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		return f, expfmt.NewFormat(expfmt.TypeTextPlain), nil
+	case "http", "https":
+		client := &http.Client{Timeout: 30 * time.Second}
+		req, err := http.NewRequest(http.MethodGet, source, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		req.Header.Set("Accept", expositionAcceptHeader)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("scraping %s: unexpected status %s", source, resp.Status)
+		}
+
+		return resp.Body, expfmt.ResponseFormat(resp.Header), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported exposition source scheme %q", u.Scheme)
+	}
+}
+
+// metricFromFamily maps one decoded Prometheus MetricFamily onto the
+// existing Metric struct. Name/Help/Type come straight from the family;
+// Labels is the union of label names observed across its samples (since an
+// exposition sample only carries the label values it actually set);
+// Buckets/Objectives are synthesized from the first histogram/summary
+// sample found, since every sample in a family shares the same schema.
+func metricFromFamily(family *dto.MetricFamily) Metric {
+	m := Metric{
+		Name: family.GetName(),
+		Help: family.GetHelp(),
+		Type: strings.ToLower(family.GetType().String()),
+	}
+
+	labelSet := make(map[string]bool)
+	for _, sample := range family.GetMetric() {
+		for _, label := range sample.GetLabel() {
+			labelSet[label.GetName()] = true
+		}
+
+		if m.Buckets == nil {
+			if h := sample.GetHistogram(); h != nil {
+				for _, bucket := range h.GetBucket() {
+					m.Buckets = append(m.Buckets, bucket.GetUpperBound())
+				}
+			}
+		}
+		if m.Objectives == nil {
+			if s := sample.GetSummary(); s != nil && len(s.GetQuantile()) > 0 {
+				m.Objectives = make(map[float64]float64, len(s.GetQuantile()))
+				for _, q := range s.GetQuantile() {
+					m.Objectives[q.GetQuantile()] = q.GetValue()
+				}
+			}
+		}
+	}
+
+	for label := range labelSet {
+		m.Labels = append(m.Labels, label)
+	}
+	sort.Strings(m.Labels)
+
+	return m
+}
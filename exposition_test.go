@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func strPtr(s string) *string                  { return &s }
+func f64Ptr(f float64) *float64                { return &f }
+func typePtr(t dto.MetricType) *dto.MetricType { return &t }
+
+func TestMetricFromFamilyGauge(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("kube_pod_info"),
+		Help: strPtr("Information about pods."),
+		Type: typePtr(dto.MetricType_GAUGE),
+		Metric: []*dto.Metric{
+			{
+				Label: []*dto.LabelPair{
+					{Name: strPtr("namespace"), Value: strPtr("default")},
+				},
+				Gauge: &dto.Gauge{Value: f64Ptr(1)},
+			},
+			{
+				Label: []*dto.LabelPair{
+					{Name: strPtr("namespace"), Value: strPtr("kube-system")},
+					{Name: strPtr("node")}, // observed only on this sample
+				},
+				Gauge: &dto.Gauge{Value: f64Ptr(1)},
+			},
+		},
+	}
+
+	got := metricFromFamily(family)
+
+	want := Metric{
+		Name:   "kube_pod_info",
+		Help:   "Information about pods.",
+		Type:   "gauge",
+		Labels: []string{"namespace", "node"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metricFromFamily() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetricFromFamilyHistogram(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("kube_pod_duration_seconds"),
+		Help: strPtr("How long pods take."),
+		Type: typePtr(dto.MetricType_HISTOGRAM),
+		Metric: []*dto.Metric{
+			{
+				Histogram: &dto.Histogram{
+					Bucket: []*dto.Bucket{
+						{UpperBound: f64Ptr(0.1)},
+						{UpperBound: f64Ptr(1)},
+					},
+				},
+			},
+		},
+	}
+
+	got := metricFromFamily(family)
+
+	if got.Type != "histogram" {
+		t.Fatalf("Type = %q, want histogram", got.Type)
+	}
+	if want := []float64{0.1, 1}; !reflect.DeepEqual(got.Buckets, want) {
+		t.Errorf("Buckets = %v, want %v", got.Buckets, want)
+	}
+}
+
+func TestMetricFromFamilySummary(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("kube_pod_size_bytes"),
+		Help: strPtr("Size of pods."),
+		Type: typePtr(dto.MetricType_SUMMARY),
+		Metric: []*dto.Metric{
+			{
+				Summary: &dto.Summary{
+					Quantile: []*dto.Quantile{
+						{Quantile: f64Ptr(0.5), Value: f64Ptr(10)},
+						{Quantile: f64Ptr(0.9), Value: f64Ptr(20)},
+					},
+				},
+			},
+		},
+	}
+
+	got := metricFromFamily(family)
+
+	want := map[float64]float64{0.5: 10, 0.9: 20}
+	if !reflect.DeepEqual(got.Objectives, want) {
+		t.Errorf("Objectives = %v, want %v", got.Objectives, want)
+	}
+}
+
+func TestIsExpositionSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"-", true},
+		{"http://localhost:8080/metrics", true},
+		{"https://localhost:8080/metrics", true},
+		{"file:///tmp/metrics.prom", true},
+		{"metrics-1.30.yaml", false},
+		{"/tmp/metrics-1.30.yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := isExpositionSource(tt.source); got != tt.want {
+			t.Errorf("isExpositionSource(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
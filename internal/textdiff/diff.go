@@ -0,0 +1,188 @@
+// Package textdiff implements a minimal, dependency-free line-level unified
+// diff, used in place of shelling out to the diff(1) binary. Shelling out
+// forks a process and writes temp files per metric, which is unnecessary
+// overhead for small in-memory texts and doesn't work on platforms (Windows,
+// scratch containers) that lack diffutils.
+package textdiff
+
+import "strings"
+
+// Op identifies what happened to a line between the old and new text.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line pairs a diff operation with the text line it applies to.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Hunk is a contiguous run of diff lines, equivalent to one "@@ ... @@"
+// section of a unified diff.
+type Hunk struct {
+	Lines []Line
+}
+
+// Options configures unified diff generation.
+type Options struct {
+	// Context is the number of unchanged lines kept around each change,
+	// mirroring diff(1)'s -U flag. A zero value means no context.
+	Context int
+}
+
+// Unified computes the line-level diff between old and new and groups it
+// into hunks with up to Context lines of surrounding, unchanged context —
+// the same shape diff(1) -U<Context> produces, minus file headers and
+// "@@ ... @@" hunk markers.
+func Unified(old, new string, opts Options) []Hunk {
+	ctx := opts.Context
+	if ctx < 0 {
+		ctx = 0
+	}
+
+	ops := align(splitLines(old), splitLines(new))
+
+	var changeIdx []int
+	for i, op := range ops {
+		if op.Op != Equal {
+			changeIdx = append(changeIdx, i)
+		}
+	}
+	if len(changeIdx) == 0 {
+		return nil
+	}
+
+	var hunks []Hunk
+	start, end := changeIdx[0], changeIdx[0]
+	for _, idx := range changeIdx[1:] {
+		equalBetween := idx - end - 1
+		if equalBetween <= ctx*2 {
+			end = idx
+			continue
+		}
+		hunks = append(hunks, buildHunk(ops, start, end, ctx))
+		start, end = idx, idx
+	}
+	hunks = append(hunks, buildHunk(ops, start, end, ctx))
+
+	return hunks
+}
+
+func buildHunk(ops []Line, start, end, ctx int) Hunk {
+	from := start - ctx
+	if from < 0 {
+		from = 0
+	}
+	to := end + ctx
+	if to > len(ops)-1 {
+		to = len(ops) - 1
+	}
+
+	lines := make([]Line, to-from+1)
+	copy(lines, ops[from:to+1])
+
+	return Hunk{Lines: lines}
+}
+
+// Format renders hunks as a unified-diff body with file headers and "@@"
+// hunk markers stripped: each context line is prefixed with ' ', each
+// removed line with '-', and each added line with '+'. Consecutive hunks are
+// separated by a "..." marker line, the way `diff -U` elides the untouched
+// lines between them.
+func Format(hunks []Hunk) string {
+	var b strings.Builder
+	for i, hunk := range hunks {
+		if i > 0 {
+			b.WriteString("...\n")
+		}
+		for _, line := range hunk.Lines {
+			switch line.Op {
+			case Insert:
+				b.WriteByte('+')
+			case Delete:
+				b.WriteByte('-')
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(line.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// UnifiedString is a convenience wrapper equivalent to Format(Unified(old,
+// new, opts)).
+func UnifiedString(old, new string, opts Options) string {
+	return Format(Unified(old, new, opts))
+}
+
+// align returns the line-by-line edit script turning oldLines into
+// newLines, computed from a classic LCS (longest common subsequence)
+// dynamic-programming table. It favors deletions over insertions when a line
+// could be explained either way, which keeps output stable for the
+// deterministic YAML the caller diffs.
+func align(oldLines, newLines []string) []Line {
+	n, m := len(oldLines), len(newLines)
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []Line
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			ops = append(ops, Line{Op: Equal, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Line{Op: Delete, Text: oldLines[i]})
+			i++
+		default:
+			ops = append(ops, Line{Op: Insert, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Line{Op: Delete, Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Line{Op: Insert, Text: newLines[j]})
+	}
+
+	return ops
+}
+
+// splitLines splits s into lines without their trailing "\n". A single
+// trailing newline is treated the way diff(1) treats it — it terminates the
+// last line rather than introducing an extra empty one — so "a\n" and "a"
+// produce the same single line "a".
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
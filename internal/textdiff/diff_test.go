@@ -0,0 +1,79 @@
+package textdiff
+
+import "testing"
+
+func TestUnifiedString(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		opts Options
+		want string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\nc\n",
+			new:  "a\nb\nc\n",
+			opts: Options{Context: 3},
+			want: "",
+		},
+		{
+			name: "single line changed with full context",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			opts: Options{Context: 999},
+			want: " a\n-b\n+x\n c\n",
+		},
+		{
+			name: "no context collapses to just the change",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			opts: Options{Context: 0},
+			want: "-b\n+x\n",
+		},
+		{
+			name: "appended line",
+			old:  "a\nb\n",
+			new:  "a\nb\nc\n",
+			opts: Options{Context: 3},
+			want: " a\n b\n+c\n",
+		},
+		{
+			name: "removed line",
+			old:  "a\nb\nc\n",
+			new:  "a\nc\n",
+			opts: Options{Context: 3},
+			want: " a\n-b\n c\n",
+		},
+		{
+			name: "missing trailing newline matches the same text with one",
+			old:  "a\nb",
+			new:  "a\nx",
+			opts: Options{Context: 3},
+			want: " a\n-b\n+x\n",
+		},
+		{
+			name: "two distant changes split into separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "1\n2\nX\n4\n5\n6\n7\n8\nY\n10\n",
+			opts: Options{Context: 1},
+			want: " 2\n-3\n+X\n 4\n...\n 8\n-9\n+Y\n 10\n",
+		},
+		{
+			name: "empty old and new",
+			old:  "",
+			new:  "",
+			opts: Options{Context: 3},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedString(tt.old, tt.new, tt.opts)
+			if got != tt.want {
+				t.Errorf("UnifiedString(%q, %q, %+v) = %q, want %q", tt.old, tt.new, tt.opts, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 
+	"github.com/tsuzu/k8s-metrics-changes/internal/textdiff"
+	"github.com/tsuzu/k8s-metrics-changes/pkg/policy"
 	"go.yaml.in/yaml/v3"
 )
 
@@ -69,62 +73,138 @@ const (
 	Added   DiffType = "Added"
 	Removed DiffType = "Removed"
 	Updated DiffType = "Updated"
+	Renamed DiffType = "Renamed"
 )
 
+// PolicyClass classifies a MetricDiff against Kubernetes' metric stability
+// guarantees (https://github.com/kubernetes/enhancements/tree/master/keps/sig-instrumentation/1209-metrics-stability).
+type PolicyClass string
+
+const (
+	Breaking      PolicyClass = "Breaking"
+	Compatible    PolicyClass = "Compatible"
+	Informational PolicyClass = "Informational"
+)
+
+// policyRank orders PolicyClass by severity so the most severe reason found
+// for a diff wins when several changes are bundled into one MetricDiff.
+var policyRank = map[PolicyClass]int{
+	Informational: 0,
+	Compatible:    1,
+	Breaking:      2,
+}
+
+// stabilityRank orders Kubernetes metric stability levels from least to most
+// mature, so a downgrade can be detected as old > new.
+var stabilityRank = map[string]int{
+	"INTERNAL": 0,
+	"ALPHA":    1,
+	"BETA":     2,
+	"STABLE":   3,
+}
+
 type MetricDiff struct {
 	Key       string
 	Type      DiffType
 	OldMetric *Metric
 	NewMetric *Metric
 	Changes   []string
+	Policy    PolicyClass
+}
+
+func bumpPolicy(cur *PolicyClass, class PolicyClass) {
+	if *cur == "" || policyRank[class] > policyRank[*cur] {
+		*cur = class
+	}
 }
 
 func compareMetrics(old, new map[string]Metric) []MetricDiff {
 	var diffs []MetricDiff
+	removedKeys := make(map[string]bool)
+	addedKeys := make(map[string]bool)
 
 	// Find added and modified metrics
 	for key, newMetric := range new {
 		if oldMetric, exists := old[key]; exists {
-			// Check for modifications
+			isStable := oldMetric.StabilityLevel == "STABLE"
+
 			var changes []string
+			policy := PolicyClass(Informational)
+
 			if oldMetric.Help != newMetric.Help {
 				changes = append(changes, "Help text changed.")
+				bumpPolicy(&policy, Informational)
 			}
 			if oldMetric.Type != newMetric.Type {
 				changes = append(changes, fmt.Sprintf("Type changed from `%s` to `%s`.", oldMetric.Type, newMetric.Type))
+				if isStable {
+					bumpPolicy(&policy, Breaking)
+				} else {
+					bumpPolicy(&policy, Informational)
+				}
 			}
 			if oldMetric.StabilityLevel != newMetric.StabilityLevel {
 				changes = append(changes, fmt.Sprintf("Stability level changed from `%s` to `%s`.", oldMetric.StabilityLevel, newMetric.StabilityLevel))
+				if isStable && stabilityRank[newMetric.StabilityLevel] < stabilityRank[oldMetric.StabilityLevel] {
+					bumpPolicy(&policy, Breaking)
+				} else {
+					bumpPolicy(&policy, Informational)
+				}
 			}
 			if oldMetric.DeprecatedVersion != newMetric.DeprecatedVersion {
 				if oldMetric.DeprecatedVersion == "" {
 					changes = append(changes, fmt.Sprintf("Marked as deprecated in version `%s`.", newMetric.DeprecatedVersion))
+					bumpPolicy(&policy, Compatible)
 				} else if newMetric.DeprecatedVersion == "" {
 					changes = append(changes, "No longer marked as deprecated.")
+					bumpPolicy(&policy, Informational)
 				} else {
 					changes = append(changes, fmt.Sprintf("Deprecated version changed from `%s` to `%s`.", oldMetric.DeprecatedVersion, newMetric.DeprecatedVersion))
+					bumpPolicy(&policy, Informational)
 				}
 			}
 			if oldMetric.AgeBuckets != newMetric.AgeBuckets {
 				changes = append(changes, fmt.Sprintf("AgeBuckets changed from `%d` to `%d`.", oldMetric.AgeBuckets, newMetric.AgeBuckets))
+				bumpPolicy(&policy, Informational)
 			}
 			if oldMetric.BufCap != newMetric.BufCap {
 				changes = append(changes, fmt.Sprintf("BufCap changed from `%d` to `%d`.", oldMetric.BufCap, newMetric.BufCap))
+				bumpPolicy(&policy, Informational)
 			}
 			if oldMetric.MaxAge != newMetric.MaxAge {
 				changes = append(changes, fmt.Sprintf("MaxAge changed from `%d` to `%d`.", oldMetric.MaxAge, newMetric.MaxAge))
+				bumpPolicy(&policy, Informational)
 			}
 			if reflect.DeepEqual(oldMetric.ConstLabels, newMetric.ConstLabels) == false {
 				changes = append(changes, "ConstLabels changed.")
+				bumpPolicy(&policy, Informational)
 			}
 
 			if !equalStringSlices(oldMetric.Labels, newMetric.Labels) {
 				labelDiff := compareLabelSlices(oldMetric.Labels, newMetric.Labels)
 				changes = append(changes, labelDiff)
+
+				added, removed := diffLabelSlices(oldMetric.Labels, newMetric.Labels)
+				if len(added) > 0 {
+					bumpPolicy(&policy, Compatible)
+				}
+				if len(removed) > 0 {
+					if isStable {
+						bumpPolicy(&policy, Breaking)
+					} else {
+						bumpPolicy(&policy, Informational)
+					}
+				}
 			}
 
 			if !equalFloat64Slices(oldMetric.Buckets, newMetric.Buckets) {
 				changes = append(changes, "Buckets changed.")
+				bumpPolicy(&policy, Informational)
+			}
+
+			if !reflect.DeepEqual(oldMetric.Objectives, newMetric.Objectives) {
+				changes = append(changes, "Objectives changed.")
+				bumpPolicy(&policy, Informational)
 			}
 
 			if len(changes) > 0 {
@@ -134,27 +214,68 @@ func compareMetrics(old, new map[string]Metric) []MetricDiff {
 					OldMetric: &oldMetric,
 					NewMetric: &newMetric,
 					Changes:   changes,
+					Policy:    policy,
 				})
 			}
 		} else {
-			// Added metric
-			diffs = append(diffs, MetricDiff{
-				Key:       key,
-				Type:      Added,
-				NewMetric: &newMetric,
-			})
+			addedKeys[key] = true
 		}
 	}
 
 	// Find removed metrics
-	for key, oldMetric := range old {
+	for key := range old {
 		if _, exists := new[key]; !exists {
-			diffs = append(diffs, MetricDiff{
-				Key:       key,
-				Type:      Removed,
-				OldMetric: &oldMetric,
-			})
+			removedKeys[key] = true
+		}
+	}
+
+	renamedOld, renamedNew := matchRenames(old, new, removedKeys, addedKeys)
+
+	for key := range addedKeys {
+		if _, isRename := renamedNew[key]; isRename {
+			continue
+		}
+		newMetric := new[key]
+		diffs = append(diffs, MetricDiff{
+			Key:       key,
+			Type:      Added,
+			NewMetric: &newMetric,
+			Policy:    Compatible,
+		})
+	}
+
+	for key := range removedKeys {
+		if _, isRename := renamedOld[key]; isRename {
+			continue
+		}
+		oldMetric := old[key]
+		policy := PolicyClass(Informational)
+		if oldMetric.StabilityLevel == "STABLE" {
+			policy = Breaking
+		}
+		diffs = append(diffs, MetricDiff{
+			Key:       key,
+			Type:      Removed,
+			OldMetric: &oldMetric,
+			Policy:    policy,
+		})
+	}
+
+	for oldKey, newKey := range renamedOld {
+		oldMetric := old[oldKey]
+		newMetric := new[newKey]
+		policy := PolicyClass(Informational)
+		if oldMetric.StabilityLevel == "STABLE" {
+			policy = Breaking
 		}
+		diffs = append(diffs, MetricDiff{
+			Key:       fmt.Sprintf("%s → %s", oldKey, newKey),
+			Type:      Renamed,
+			OldMetric: &oldMetric,
+			NewMetric: &newMetric,
+			Changes:   []string{fmt.Sprintf("Renamed from `%s` to `%s`.", oldKey, newKey)},
+			Policy:    policy,
+		})
 	}
 
 	// Sort diffs by key for consistent output
@@ -165,6 +286,97 @@ func compareMetrics(old, new map[string]Metric) []MetricDiff {
 	return diffs
 }
 
+// matchRenames performs a fuzzy key match between removed and added metrics
+// to detect Namespace/Subsystem/Name changes that are really the same metric
+// (same Type, Help and Labels) under a new key. It returns the matched keys
+// as oldKey -> newKey and newKey -> oldKey lookups.
+func matchRenames(old, new map[string]Metric, removedKeys, addedKeys map[string]bool) (map[string]string, map[string]string) {
+	renamedOld := make(map[string]string)
+	renamedNew := make(map[string]string)
+
+	// Iterate in sorted key order, and among multiple candidates for the
+	// same oldKey pick the lexically closest newKey, so the pairing is
+	// deterministic regardless of map iteration order.
+	for _, oldKey := range sortedKeys(removedKeys) {
+		oldMetric := old[oldKey]
+
+		bestKey := ""
+		bestDist := -1
+		for _, newKey := range sortedKeys(addedKeys) {
+			if renamedNew[newKey] != "" {
+				continue
+			}
+			newMetric := new[newKey]
+			if oldMetric.Type != newMetric.Type ||
+				oldMetric.Help != newMetric.Help ||
+				!equalStringSlices(oldMetric.Labels, newMetric.Labels) {
+				continue
+			}
+			dist := levenshteinDistance(oldKey, newKey)
+			if bestDist == -1 || dist < bestDist {
+				bestDist = dist
+				bestKey = newKey
+			}
+		}
+
+		if bestKey != "" {
+			renamedOld[oldKey] = bestKey
+			renamedNew[bestKey] = oldKey
+		}
+	}
+
+	return renamedOld, renamedNew
+}
+
+// sortedKeys returns the keys of set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// levenshteinDistance returns the classic single-character-edit distance
+// between a and b, used to break ties between multiple rename candidates
+// that otherwise match on Type/Help/Labels.
+func levenshteinDistance(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
 func equalStringSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -177,7 +389,9 @@ func equalStringSlices(a, b []string) bool {
 	return true
 }
 
-func compareLabelSlices(oldLabels, newLabels []string) string {
+// diffLabelSlices returns the labels added and removed between oldLabels and
+// newLabels, sorted for deterministic output.
+func diffLabelSlices(oldLabels, newLabels []string) (added, removed []string) {
 	oldSet := make(map[string]bool)
 	newSet := make(map[string]bool)
 
@@ -188,31 +402,40 @@ func compareLabelSlices(oldLabels, newLabels []string) string {
 		newSet[label] = true
 	}
 
-	var added, removed []string
-
-	// Find added labels
 	for label := range newSet {
 		if !oldSet[label] {
-			added = append(added, fmt.Sprintf("`%s`", label))
+			added = append(added, label)
 		}
 	}
-
-	// Find removed labels
 	for label := range oldSet {
 		if !newSet[label] {
-			removed = append(removed, fmt.Sprintf("`%s`", label))
+			removed = append(removed, label)
 		}
 	}
 
 	sort.Strings(added)
 	sort.Strings(removed)
 
+	return added, removed
+}
+
+func compareLabelSlices(oldLabels, newLabels []string) string {
+	added, removed := diffLabelSlices(oldLabels, newLabels)
+
 	var changes []string
 	if len(added) > 0 {
-		changes = append(changes, fmt.Sprintf("Added labels: [%s].", strings.Join(added, ", ")))
+		quoted := make([]string, len(added))
+		for i, label := range added {
+			quoted[i] = fmt.Sprintf("`%s`", label)
+		}
+		changes = append(changes, fmt.Sprintf("Added labels: [%s].", strings.Join(quoted, ", ")))
 	}
 	if len(removed) > 0 {
-		changes = append(changes, fmt.Sprintf("Removed labels: [%s].", strings.Join(removed, ", ")))
+		quoted := make([]string, len(removed))
+		for i, label := range removed {
+			quoted[i] = fmt.Sprintf("`%s`", label)
+		}
+		changes = append(changes, fmt.Sprintf("Removed labels: [%s].", strings.Join(quoted, ", ")))
 	}
 
 	if len(changes) == 0 {
@@ -235,7 +458,11 @@ func equalFloat64Slices(a, b []float64) bool {
 	return true
 }
 
-func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
+// defaultDiffContext keeps every changed-metric YAML block shown in full,
+// matching the historic `diff -U999999` behavior.
+const defaultDiffContext = 999999
+
+func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string, diffContext int) {
 	fmt.Printf("# Kubernetes Metrics Changes: %s → %s\n", oldVersion, newVersion)
 	fmt.Println()
 
@@ -245,7 +472,8 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 	}
 
 	// Count changes by type
-	var added, removed, updated int
+	var added, removed, updated, renamed int
+	var breaking, compatible, informational int
 	for _, diff := range diffs {
 		switch diff.Type {
 		case Added:
@@ -254,6 +482,17 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 			removed++
 		case Updated:
 			updated++
+		case Renamed:
+			renamed++
+		}
+
+		switch diff.Policy {
+		case Breaking:
+			breaking++
+		case Compatible:
+			compatible++
+		case Informational:
+			informational++
 		}
 	}
 
@@ -261,12 +500,14 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 	fmt.Printf("- **Added**: %d metrics\n", added)
 	fmt.Printf("- **Removed**: %d metrics\n", removed)
 	fmt.Printf("- **Updated**: %d metrics\n", updated)
-	fmt.Printf("- **Total Changes**: %d metrics\n\n", len(diffs))
+	fmt.Printf("- **Renamed**: %d metrics\n", renamed)
+	fmt.Printf("- **Total Changes**: %d metrics\n", len(diffs))
+	fmt.Printf("- **Breaking**: %d, **Compatible**: %d, **Informational**: %d\n\n", breaking, compatible, informational)
 
 	fmt.Println("## Changed Metrics")
 	fmt.Println()
-	fmt.Println("| Metric Name | Type | Change Type | Stability Level | Description |")
-	fmt.Println("|-------------|------|-------------|----------------|-------------|")
+	fmt.Println("| Metric Name | Type | Change Type | Policy | Stability Level | Description |")
+	fmt.Println("|-------------|------|-------------|--------|----------------|-------------|")
 
 	for _, diff := range diffs {
 		name := diff.Key
@@ -282,7 +523,7 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 			metricType = diff.OldMetric.Type
 			stabilityLevel = diff.OldMetric.StabilityLevel
 			// description = truncateString(diff.OldMetric.Help, 100)
-		case Updated:
+		case Updated, Renamed:
 			metricType = diff.NewMetric.Type
 			stabilityLevel = diff.NewMetric.StabilityLevel
 			description = strings.Join(diff.Changes, " <br> ")
@@ -291,8 +532,8 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 		// Escape pipe characters in description
 		description = strings.ReplaceAll(description, "|", "\\|")
 
-		fmt.Printf("| [%s](#%s) | %s | %s | `%s` | %s |\n",
-			name, name, metricType, diff.Type, stabilityLevel, description)
+		fmt.Printf("| [%s](#%s) | %s | %s | %s | `%s` | %s |\n",
+			name, anchor(name), metricType, diff.Type, diff.Policy, stabilityLevel, description)
 	}
 
 	fmt.Println("## Detailed Changes")
@@ -317,7 +558,7 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 			}
 		}
 
-		ud := unifiedDiffWithoutHeader(string(old), string(new))
+		ud := textdiff.UnifiedString(string(old), string(new), textdiff.Options{Context: diffContext})
 
 		fmt.Println("```diff")
 		fmt.Print(ud)
@@ -326,68 +567,235 @@ func printMarkdownTable(diffs []MetricDiff, oldVersion, newVersion string) {
 	}
 }
 
-func unifiedDiffWithoutHeader(old, new string) string {
-	oldFile, err := os.CreateTemp("", "")
-	if err != nil {
-		log.Fatalf("Error creating temp file: %v", err)
-	}
-	defer os.Remove(oldFile.Name())
-	defer oldFile.Close()
+// printLifetimeView renders the aggregate per-metric history built by
+// buildTimelines: when each metric was introduced, how it changed release
+// over release, and when (if ever) it was deprecated or removed.
+func printLifetimeView(timelines map[string]*Timeline) {
+	fmt.Println("## Metric Lifetime")
+	fmt.Println()
 
-	newFile, err := os.CreateTemp("", "")
-	if err != nil {
-		log.Fatalf("Error creating temp file: %v", err)
+	if len(timelines) == 0 {
+		fmt.Println("No metrics observed across the walked versions.")
+		return
 	}
-	defer os.Remove(newFile.Name())
-	defer newFile.Close()
 
-	if _, err := oldFile.WriteString(old); err != nil {
-		log.Fatalf("Error writing to temp file: %v", err)
-	}
-	if _, err := newFile.WriteString(new); err != nil {
-		log.Fatalf("Error writing to temp file: %v", err)
+	keys := make([]string, 0, len(timelines))
+	for key := range timelines {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	output, err := exec.Command("diff", "-U999999", oldFile.Name(), newFile.Name()).CombinedOutput()
-	if exitError, ok := err.(*exec.ExitError); !ok || exitError.ExitCode() != 1 {
-		log.Fatalf("Error running diff command: %s %v", string(output), err)
+	for _, key := range keys {
+		fmt.Printf("### %s\n\n", key)
+		for _, transition := range timelines[key].Transitions {
+			fmt.Printf("- `%s`: %s\n", transition.Version, transition.Event)
+		}
+		fmt.Println()
 	}
+}
 
-	lines := strings.Split(string(output), "\n")
-	if len(lines) <= 3 {
-		return ""
+// anchor turns a metric diff key into a GitHub-style Markdown heading anchor
+// so the summary table can link to a "### <key>" section even when the key
+// contains characters (like the "→" in a Renamed diff) that aren't valid in
+// an anchor as-is.
+func anchor(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
 	}
-	return strings.Join(lines[3:], "\n")
+	return b.String()
 }
 
 func versionFromPath(path string) string {
+	if isExpositionSource(path) {
+		return path
+	}
+
 	base := filepath.Base(path)
 
 	return strings.TrimSuffix(base, filepath.Ext(base))
 }
 
+// jsonSummary is the machine-readable counterpart to the Markdown report,
+// written to --json-summary so CI and other tooling can consume the policy
+// classification without scraping Markdown. It covers every consecutive
+// version pair walked, plus the aggregate per-metric Timeline when more than
+// two versions are walked (with only two, Timelines is omitted).
+type jsonSummary struct {
+	Pairs     []jsonPairSummary     `json:"pairs"`
+	Timelines []jsonTimelineSummary `json:"timelines,omitempty"`
+}
+
+type jsonPairSummary struct {
+	OldVersion string           `json:"oldVersion"`
+	NewVersion string           `json:"newVersion"`
+	Diffs      []jsonSummaryRow `json:"diffs"`
+}
+
+type jsonSummaryRow struct {
+	Key     string      `json:"key"`
+	Type    DiffType    `json:"type"`
+	Policy  PolicyClass `json:"policy"`
+	Changes []string    `json:"changes,omitempty"`
+}
+
+type jsonTimelineSummary struct {
+	Key         string       `json:"key"`
+	Transitions []Transition `json:"transitions"`
+}
+
+func writeJSONSummary(path string, pairDiffs []PairDiff, timelines map[string]*Timeline) error {
+	summary := jsonSummary{
+		Pairs: make([]jsonPairSummary, 0, len(pairDiffs)),
+	}
+	for _, pair := range pairDiffs {
+		pairSummary := jsonPairSummary{
+			OldVersion: pair.OldVersion,
+			NewVersion: pair.NewVersion,
+		}
+		for _, diff := range pair.Diffs {
+			pairSummary.Diffs = append(pairSummary.Diffs, jsonSummaryRow{
+				Key:     diff.Key,
+				Type:    diff.Type,
+				Policy:  diff.Policy,
+				Changes: diff.Changes,
+			})
+		}
+		summary.Pairs = append(summary.Pairs, pairSummary)
+	}
+
+	keys := make([]string, 0, len(timelines))
+	for key := range timelines {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		summary.Timelines = append(summary.Timelines, jsonTimelineSummary{
+			Key:         key,
+			Transitions: timelines[key].Transitions,
+		})
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// worstPairPolicy returns the most severe PolicyClass found across every
+// diff in pairDiffs, or "" if there are none.
+func worstPairPolicy(pairDiffs []PairDiff) PolicyClass {
+	var worst PolicyClass
+	for _, pair := range pairDiffs {
+		for _, diff := range pair.Diffs {
+			bumpPolicy(&worst, diff.Policy)
+		}
+	}
+	return worst
+}
+
 func main() {
-	if len(os.Args) != 3 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <old.yaml> <new.yaml>\n", os.Args[0])
+	failOn := flag.String("fail-on", "", "exit non-zero if any diff is classified at or above this policy level (breaking|compatible)")
+	jsonSummaryPath := flag.String("json-summary", "", "path to write a machine-readable JSON summary alongside the Markdown report")
+	diffContext := flag.Int("diff-context", defaultDiffContext, "number of context lines to show around each changed metric field in the per-metric diff")
+	cardinalityRulesPath := flag.String("cardinality-rules", "", "path to a YAML file of label-name -> tier overrides (low|medium|high|unbounded) for the cardinality analyzer")
+	histogramBucketThreshold := flag.Int("histogram-bucket-threshold", defaultHistogramBucketThreshold, "flag new histograms with more buckets than this")
+	cardinalitySARIFPath := flag.String("cardinality-sarif", "", "path to write cardinality warnings as a SARIF 2.1.0 log")
+	rulesDir := flag.String("rules", "", "directory of additional .rego files evaluated alongside the bundled starter policy rules")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [--fail-on=breaking|compatible] [--json-summary=path] <old.yaml> <new.yaml> [more.yaml ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [--fail-on=breaking|compatible] [--json-summary=path] <dir-of-yaml-files>\n", os.Args[0])
 		os.Exit(1)
 	}
 
-	oldFile := os.Args[1]
-	newFile := os.Args[2]
+	var failOnThreshold PolicyClass
+	switch *failOn {
+	case "":
+		// no policy gate
+	case "breaking":
+		failOnThreshold = Breaking
+	case "compatible":
+		failOnThreshold = Compatible
+	default:
+		log.Fatalf("Invalid --fail-on value %q: must be \"breaking\" or \"compatible\"", *failOn)
+	}
+
+	paths, err := resolveSnapshotPaths(args)
+	if err != nil {
+		log.Fatalf("Error resolving input files: %v", err)
+	}
+	if len(paths) < 2 {
+		log.Fatalf("Need at least 2 version files to diff, got %d", len(paths))
+	}
+
+	snapshots, err := loadSnapshots(paths)
+	if err != nil {
+		log.Fatalf("Error loading snapshots: %v", err)
+	}
 
-	oldMetrics, err := loadMetrics(oldFile)
+	cardinalityRules, err := loadCardinalityRules(*cardinalityRulesPath)
 	if err != nil {
-		log.Fatalf("Error loading %s: %v", oldFile, err)
+		log.Fatalf("Error loading cardinality rules from %s: %v", *cardinalityRulesPath, err)
 	}
 
-	newMetrics, err := loadMetrics(newFile)
+	policyEngine, err := policy.NewEngine(context.Background(), *rulesDir)
 	if err != nil {
-		log.Fatalf("Error loading %s: %v", newFile, err)
+		log.Fatalf("Error initializing policy engine: %v", err)
 	}
 
-	oldVersion := versionFromPath(oldFile)
-	newVersion := versionFromPath(newFile)
+	pairDiffs := walkVersions(snapshots)
+	var denyFound bool
+	for _, pair := range pairDiffs {
+		printMarkdownTable(pair.Diffs, pair.OldVersion, pair.NewVersion, *diffContext)
+		printCardinalityWarnings(analyzeCardinality(pair.Diffs, cardinalityRules, *histogramBucketThreshold))
 
-	diffs := compareMetrics(oldMetrics, newMetrics)
-	printMarkdownTable(diffs, oldVersion, newVersion)
+		violations, err := evaluateCustomPolicy(context.Background(), policyEngine, pair.Diffs)
+		if err != nil {
+			log.Fatalf("Error evaluating custom policy rules: %v", err)
+		}
+		printPolicyViolations(violations)
+		if anyDeny(violations) {
+			denyFound = true
+		}
+	}
+
+	if *cardinalitySARIFPath != "" {
+		if err := writeCardinalitySARIF(*cardinalitySARIFPath, pairDiffs, cardinalityRules, *histogramBucketThreshold); err != nil {
+			log.Fatalf("Error writing cardinality SARIF to %s: %v", *cardinalitySARIFPath, err)
+		}
+	}
+
+	if len(pairDiffs) > 1 {
+		timelines := buildTimelines(snapshots, pairDiffs)
+		printLifetimeView(timelines)
+
+		if *jsonSummaryPath != "" {
+			if err := writeJSONSummary(*jsonSummaryPath, pairDiffs, timelines); err != nil {
+				log.Fatalf("Error writing JSON summary to %s: %v", *jsonSummaryPath, err)
+			}
+		}
+	} else if *jsonSummaryPath != "" {
+		if err := writeJSONSummary(*jsonSummaryPath, pairDiffs, nil); err != nil {
+			log.Fatalf("Error writing JSON summary to %s: %v", *jsonSummaryPath, err)
+		}
+	}
+
+	if failOnThreshold != "" && policyRank[worstPairPolicy(pairDiffs)] >= policyRank[failOnThreshold] {
+		fmt.Fprintf(os.Stderr, "Policy violation: changes include diffs at or above %q severity (see report).\n", failOnThreshold)
+		os.Exit(1)
+	}
+	if denyFound {
+		fmt.Fprintln(os.Stderr, "Policy violation: one or more custom Rego rules denied a change (see report).")
+		os.Exit(1)
+	}
 }
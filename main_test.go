@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchRenames(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         map[string]Metric
+		new         map[string]Metric
+		removedKeys map[string]bool
+		addedKeys   map[string]bool
+		wantOld     map[string]string
+		wantNew     map[string]string
+	}{
+		{
+			name: "single unambiguous rename",
+			old: map[string]Metric{
+				"kube_pod_old": {Type: "gauge", Help: "A pod gauge.", Labels: []string{"namespace"}},
+			},
+			new: map[string]Metric{
+				"kube_pod_new": {Type: "gauge", Help: "A pod gauge.", Labels: []string{"namespace"}},
+			},
+			removedKeys: map[string]bool{"kube_pod_old": true},
+			addedKeys:   map[string]bool{"kube_pod_new": true},
+			wantOld:     map[string]string{"kube_pod_old": "kube_pod_new"},
+			wantNew:     map[string]string{"kube_pod_new": "kube_pod_old"},
+		},
+		{
+			name: "no candidate matches on type or labels",
+			old: map[string]Metric{
+				"kube_pod_old": {Type: "gauge", Help: "A pod gauge.", Labels: []string{"namespace"}},
+			},
+			new: map[string]Metric{
+				"kube_pod_new": {Type: "counter", Help: "A pod gauge.", Labels: []string{"namespace"}},
+			},
+			removedKeys: map[string]bool{"kube_pod_old": true},
+			addedKeys:   map[string]bool{"kube_pod_new": true},
+			wantOld:     map[string]string{},
+			wantNew:     map[string]string{},
+		},
+		{
+			name: "ambiguous pair resolves to the lexically closest key, deterministically",
+			old: map[string]Metric{
+				"kube_pod_a": {Type: "gauge", Help: "Same help.", Labels: []string{"namespace"}},
+				"kube_pod_b": {Type: "gauge", Help: "Same help.", Labels: []string{"namespace"}},
+			},
+			new: map[string]Metric{
+				"kube_pod_c": {Type: "gauge", Help: "Same help.", Labels: []string{"namespace"}},
+				"kube_pod_d": {Type: "gauge", Help: "Same help.", Labels: []string{"namespace"}},
+			},
+			removedKeys: map[string]bool{"kube_pod_a": true, "kube_pod_b": true},
+			addedKeys:   map[string]bool{"kube_pod_c": true, "kube_pod_d": true},
+			wantOld:     map[string]string{"kube_pod_a": "kube_pod_c", "kube_pod_b": "kube_pod_d"},
+			wantNew:     map[string]string{"kube_pod_c": "kube_pod_a", "kube_pod_d": "kube_pod_b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				gotOld, gotNew := matchRenames(tt.old, tt.new, tt.removedKeys, tt.addedKeys)
+				if !reflect.DeepEqual(gotOld, tt.wantOld) {
+					t.Fatalf("renamedOld = %v, want %v", gotOld, tt.wantOld)
+				}
+				if !reflect.DeepEqual(gotNew, tt.wantNew) {
+					t.Fatalf("renamedNew = %v, want %v", gotNew, tt.wantNew)
+				}
+			}
+		})
+	}
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"kube_pod_a", "kube_pod_c", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshteinDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
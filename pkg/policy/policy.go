@@ -0,0 +1,142 @@
+// Package policy lets operators encode organization-specific metric
+// compatibility guarantees as OPA/Rego rules, evaluated against each diff the
+// tool produces, instead of requiring a patch to the tool itself.
+package policy
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+//go:embed rules/*.rego
+var starterRulesFS embed.FS
+
+// query is the Rego query every loaded rule set is evaluated against: the
+// `metricpolicy` package's `deny` and `warn` partial sets.
+const query = "data.metricpolicy"
+
+// Verdict is a single deny or warn message a rule produced for one diff.
+type Verdict struct {
+	Deny    bool
+	Message string
+}
+
+// Engine evaluates the starter rules plus any user-supplied .rego files
+// against diff input shaped as JSON.
+type Engine struct {
+	query rego.PreparedEvalQuery
+}
+
+// NewEngine prepares the bundled starter rules, plus every *.rego file in
+// rulesDir if one is given, for repeated evaluation.
+func NewEngine(ctx context.Context, rulesDir string) (*Engine, error) {
+	starter, err := starterModules()
+	if err != nil {
+		return nil, fmt.Errorf("loading starter policy rules: %w", err)
+	}
+
+	opts := []func(*rego.Rego){rego.Query(query), rego.SetRegoVersion(ast.RegoV1)}
+	for name, content := range starter {
+		opts = append(opts, rego.Module(name, content))
+	}
+
+	if rulesDir != "" {
+		files, err := regoFiles(rulesDir)
+		if err != nil {
+			return nil, fmt.Errorf("listing rego rules in %s: %w", rulesDir, err)
+		}
+		opts = append(opts, rego.Load(files, nil))
+	}
+
+	prepared, err := rego.New(opts...).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("preparing policy rules: %w", err)
+	}
+
+	return &Engine{query: prepared}, nil
+}
+
+// Evaluate runs every loaded rule against input — a JSON-marshalable value,
+// typically a diff — and returns every deny/warn message produced.
+func (e *Engine) Evaluate(ctx context.Context, input any) ([]Verdict, error) {
+	rs, err := e.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("evaluating policy rules: %w", err)
+	}
+
+	var verdicts []Verdict
+	for _, result := range rs {
+		for _, expr := range result.Expressions {
+			obj, ok := expr.Value.(map[string]any)
+			if !ok {
+				continue
+			}
+			verdicts = append(verdicts, messagesFromSet(obj, "deny", true)...)
+			verdicts = append(verdicts, messagesFromSet(obj, "warn", false)...)
+		}
+	}
+
+	return verdicts, nil
+}
+
+func messagesFromSet(obj map[string]any, key string, deny bool) []Verdict {
+	set, ok := obj[key].([]any)
+	if !ok {
+		return nil
+	}
+
+	var verdicts []Verdict
+	for _, item := range set {
+		msg, ok := item.(string)
+		if !ok {
+			continue
+		}
+		verdicts = append(verdicts, Verdict{Deny: deny, Message: msg})
+	}
+
+	return verdicts
+}
+
+func starterModules() (map[string]string, error) {
+	entries, err := starterRulesFS.ReadDir("rules")
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := starterRulesFS.ReadFile(filepath.Join("rules", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		modules["rules/"+entry.Name()] = string(data)
+	}
+
+	return modules, nil
+}
+
+func regoFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".rego") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEngineStarterRules(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, "")
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		input     map[string]any
+		wantDeny  bool
+		wantWarn  bool
+		wantEmpty bool
+	}{
+		{
+			name: "removing a STABLE metric without deprecation denies",
+			input: map[string]any{
+				"key":  "kube_pod_info",
+				"type": "Removed",
+				"oldMetric": map[string]any{
+					"stabilityLevel": "STABLE",
+				},
+			},
+			wantDeny: true,
+		},
+		{
+			name: "removing a deprecated STABLE metric is allowed",
+			input: map[string]any{
+				"key":  "kube_pod_info",
+				"type": "Removed",
+				"oldMetric": map[string]any{
+					"stabilityLevel":    "STABLE",
+					"deprecatedVersion": "1.30",
+				},
+			},
+			wantEmpty: true,
+		},
+		{
+			name: "narrowing histogram buckets denies",
+			input: map[string]any{
+				"key":  "kube_pod_duration_seconds",
+				"type": "Updated",
+				"oldMetric": map[string]any{
+					"type":    "histogram",
+					"buckets": []any{0.1, 0.5, 1, 5, 10},
+				},
+				"newMetric": map[string]any{
+					"type":    "histogram",
+					"buckets": []any{0.1, 1, 10},
+				},
+			},
+			wantDeny: true,
+		},
+		{
+			name: "changing constLabels on a STABLE metric warns",
+			input: map[string]any{
+				"key":  "kube_pod_info",
+				"type": "Updated",
+				"oldMetric": map[string]any{
+					"stabilityLevel": "STABLE",
+					"constLabels":    map[string]any{"foo": "bar"},
+				},
+				"newMetric": map[string]any{
+					"stabilityLevel": "STABLE",
+					"constLabels":    map[string]any{"foo": "baz"},
+				},
+			},
+			wantWarn: true,
+		},
+		{
+			name: "an unrelated diff produces no verdicts",
+			input: map[string]any{
+				"key":  "kube_pod_info",
+				"type": "Added",
+			},
+			wantEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdicts, err := engine.Evaluate(ctx, tt.input)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+
+			if tt.wantEmpty {
+				if len(verdicts) != 0 {
+					t.Fatalf("verdicts = %v, want none", verdicts)
+				}
+				return
+			}
+
+			if len(verdicts) == 0 {
+				t.Fatalf("verdicts = empty, want at least one")
+			}
+			if verdicts[0].Deny != tt.wantDeny {
+				t.Errorf("verdicts[0].Deny = %v, want %v", verdicts[0].Deny, tt.wantDeny)
+			}
+		})
+	}
+}
+
+func TestEngineLoadsUserSuppliedRules(t *testing.T) {
+	dir := t.TempDir()
+	rule := `package metricpolicy
+
+warn contains msg if {
+	input.type == "Added"
+	msg := sprintf("new metric introduced: %s", [input.key])
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "custom.rego"), []byte(rule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, dir)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	verdicts, err := engine.Evaluate(ctx, map[string]any{
+		"key":  "kube_pod_new_metric",
+		"type": "Added",
+	})
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+
+	if len(verdicts) != 1 || verdicts[0].Deny {
+		t.Fatalf("verdicts = %v, want one warn verdict", verdicts)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tsuzu/k8s-metrics-changes/pkg/policy"
+)
+
+// diffPolicyInput is the JSON shape handed to each Rego rule: a single
+// MetricDiff, with its full old/new Metric so rules can inspect any field.
+type diffPolicyInput struct {
+	Key       string   `json:"key"`
+	Type      DiffType `json:"type"`
+	Changes   []string `json:"changes,omitempty"`
+	OldMetric *Metric  `json:"oldMetric,omitempty"`
+	NewMetric *Metric  `json:"newMetric,omitempty"`
+}
+
+// PolicyViolation pairs a diff's key with the custom Rego verdict raised
+// against it.
+type PolicyViolation struct {
+	Key     string
+	Deny    bool
+	Message string
+}
+
+// evaluateCustomPolicy runs every diff in diffs through engine and collects
+// the deny/warn messages raised, in diff order.
+func evaluateCustomPolicy(ctx context.Context, engine *policy.Engine, diffs []MetricDiff) ([]PolicyViolation, error) {
+	var violations []PolicyViolation
+
+	for _, diff := range diffs {
+		input, err := toPolicyInput(diff)
+		if err != nil {
+			return nil, fmt.Errorf("encoding diff %s for policy evaluation: %w", diff.Key, err)
+		}
+
+		verdicts, err := engine.Evaluate(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating policy for %s: %w", diff.Key, err)
+		}
+
+		for _, verdict := range verdicts {
+			violations = append(violations, PolicyViolation{
+				Key:     diff.Key,
+				Deny:    verdict.Deny,
+				Message: verdict.Message,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// toPolicyInput marshals a MetricDiff to the map[string]any shape OPA
+// expects, going through JSON so Rego sees plain strings/numbers/objects
+// rather than Go struct types.
+func toPolicyInput(diff MetricDiff) (map[string]any, error) {
+	data, err := json.Marshal(diffPolicyInput{
+		Key:       diff.Key,
+		Type:      diff.Type,
+		Changes:   diff.Changes,
+		OldMetric: diff.OldMetric,
+		NewMetric: diff.NewMetric,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var input map[string]any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// printPolicyViolations renders a "Custom Policy Violations" Markdown
+// section for the deny/warn messages raised against one version pair.
+func printPolicyViolations(violations []PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	fmt.Println("## Custom Policy Violations")
+	fmt.Println()
+
+	for _, violation := range violations {
+		marker := "⚠️ WARN"
+		if violation.Deny {
+			marker = "🛑 DENY"
+		}
+		fmt.Printf("- **%s** [%s](#%s): %s\n", marker, violation.Key, anchor(violation.Key), violation.Message)
+	}
+	fmt.Println()
+}
+
+// anyDeny reports whether violations contains at least one deny-level
+// verdict.
+func anyDeny(violations []PolicyViolation) bool {
+	for _, violation := range violations {
+		if violation.Deny {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// sarifLog is a minimal SARIF 2.1.0 document (https://docs.oasis-open.org/sarif/sarif/v2.1.0/)
+// carrying just enough structure for cardinality warnings to show up as a
+// PR check's annotations.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps a CardinalityTier to a SARIF result level, so a PR check
+// can decide which severities to fail on.
+func sarifLevel(tier CardinalityTier) string {
+	switch tier {
+	case CardinalityUnbounded, CardinalityHigh:
+		return "error"
+	case CardinalityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// writeCardinalitySARIF writes warnings as a SARIF 2.1.0 log so they can be
+// surfaced as inline PR annotations by tooling that understands the format.
+// Each result's artifact URI is the version label of the newer snapshot in
+// the pair it was found in (versionFromPath: the file's base name without
+// its extension, or the exposition source as given), not the file's path.
+func writeCardinalitySARIF(path string, pairs []PairDiff, rules map[string]CardinalityTier, bucketThreshold int) error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: "k8s-metrics-changes-cardinality",
+				Rules: []sarifRule{
+					{ID: "cardinality-risk", Name: "CardinalityRisk"},
+				},
+			}},
+		}},
+	}
+
+	for _, pair := range pairs {
+		for _, warning := range analyzeCardinality(pair.Diffs, rules, bucketThreshold) {
+			message := ""
+			for i, reason := range warning.Reasons {
+				if i > 0 {
+					message += " "
+				}
+				message += reason
+			}
+
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+				RuleID: "cardinality-risk",
+				Level:  sarifLevel(warning.Tier),
+				Message: sarifMessage{
+					Text: message,
+				},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: pair.NewVersion},
+					},
+				}},
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snapshot is one version's worth of metrics, labeled with the version
+// string derived from its source file name.
+type Snapshot struct {
+	Version string
+	Metrics map[string]Metric
+}
+
+// resolveSnapshotPaths expands args into an ordered list of metric dump
+// files. A single directory argument is expanded to every *.yaml/*.yml file
+// in it, sorted by name; any other argument list is used as-is, in the
+// order given, so callers control the version ordering directly.
+func resolveSnapshotPaths(args []string) ([]string, error) {
+	if len(args) == 1 && !isExpositionSource(args[0]) {
+		info, err := os.Stat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return dirYAMLFiles(args[0])
+		}
+	}
+
+	return args, nil
+}
+
+func dirYAMLFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// loadSnapshots loads each path in order into a Snapshot, naming it after
+// versionFromPath(path).
+func loadSnapshots(paths []string) ([]Snapshot, error) {
+	snapshots := make([]Snapshot, 0, len(paths))
+	for _, path := range paths {
+		metrics, err := loadMetricsFromSource(path)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{
+			Version: versionFromPath(path),
+			Metrics: metrics,
+		})
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveSnapshotPathsExpandsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1.31.yaml", "1.30.yml", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := resolveSnapshotPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("resolveSnapshotPaths: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "1.30.yml"),
+		filepath.Join(dir, "1.31.yaml"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveSnapshotPaths(%q) = %v, want %v", dir, got, want)
+	}
+}
+
+func TestResolveSnapshotPathsPassesExplicitListThrough(t *testing.T) {
+	args := []string{"b.yaml", "a.yaml"}
+
+	got, err := resolveSnapshotPaths(args)
+	if err != nil {
+		t.Fatalf("resolveSnapshotPaths: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("resolveSnapshotPaths(%v) = %v, want unchanged %v", args, got, args)
+	}
+}
+
+func TestResolveSnapshotPathsSkipsStatForExpositionSource(t *testing.T) {
+	args := []string{"http://localhost:8080/metrics"}
+
+	got, err := resolveSnapshotPaths(args)
+	if err != nil {
+		t.Fatalf("resolveSnapshotPaths: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("resolveSnapshotPaths(%v) = %v, want unchanged %v", args, got, args)
+	}
+}
+
+func TestDirYAMLFilesSortedAndFiltered(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"c.yaml", "a.yml", "b.YAML", "skip.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir.yaml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := dirYAMLFiles(dir)
+	if err != nil {
+		t.Fatalf("dirYAMLFiles: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "a.yml"),
+		filepath.Join(dir, "b.YAML"),
+		filepath.Join(dir, "c.yaml"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dirYAMLFiles(%q) = %v, want %v", dir, got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PairDiff is the diff between two consecutive snapshots in a multi-version
+// walk, alongside the version labels it spans.
+type PairDiff struct {
+	OldVersion string
+	NewVersion string
+	Diffs      []MetricDiff
+}
+
+// walkVersions diffs each consecutive pair of snapshots, producing one
+// PairDiff per transition. It requires at least two snapshots.
+func walkVersions(snapshots []Snapshot) []PairDiff {
+	pairDiffs := make([]PairDiff, 0, len(snapshots)-1)
+	for i := 1; i < len(snapshots); i++ {
+		old, new := snapshots[i-1], snapshots[i]
+		pairDiffs = append(pairDiffs, PairDiff{
+			OldVersion: old.Version,
+			NewVersion: new.Version,
+			Diffs:      compareMetrics(old.Metrics, new.Metrics),
+		})
+	}
+
+	return pairDiffs
+}
+
+// Transition is a single event in a metric's Timeline, anchored to the
+// version it was first observed in.
+type Transition struct {
+	Version string
+	Event   string
+}
+
+// Timeline records every transition observed for one metric key across a
+// multi-version walk: when it appeared, how it changed, and when (if ever)
+// it was deprecated or removed.
+type Timeline struct {
+	Key         string
+	Transitions []Transition
+}
+
+// buildTimelines folds a series of PairDiffs into one Timeline per metric
+// key, keyed by metricKey. Metrics already present in the first snapshot are
+// recorded as "Present" there rather than "Introduced", since their true
+// introduction may predate the walk.
+func buildTimelines(snapshots []Snapshot, pairDiffs []PairDiff) map[string]*Timeline {
+	timelines := make(map[string]*Timeline)
+
+	get := func(key string) *Timeline {
+		t, ok := timelines[key]
+		if !ok {
+			t = &Timeline{Key: key}
+			timelines[key] = t
+		}
+		return t
+	}
+
+	if len(snapshots) > 0 {
+		first := snapshots[0]
+		keys := make([]string, 0, len(first.Metrics))
+		for key := range first.Metrics {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			get(key).Transitions = append(get(key).Transitions, Transition{
+				Version: first.Version,
+				Event:   fmt.Sprintf("Present as of `%s`.", first.Version),
+			})
+		}
+	}
+
+	for _, pair := range pairDiffs {
+		for _, diff := range pair.Diffs {
+			switch diff.Type {
+			case Added:
+				get(diff.Key).Transitions = append(get(diff.Key).Transitions, Transition{
+					Version: pair.NewVersion,
+					Event:   fmt.Sprintf("Introduced in `%s`.", pair.NewVersion),
+				})
+			case Removed:
+				get(diff.Key).Transitions = append(get(diff.Key).Transitions, Transition{
+					Version: pair.NewVersion,
+					Event:   fmt.Sprintf("Removed in `%s`.", pair.NewVersion),
+				})
+			case Updated:
+				for _, change := range diff.Changes {
+					get(diff.Key).Transitions = append(get(diff.Key).Transitions, Transition{
+						Version: pair.NewVersion,
+						Event:   change,
+					})
+				}
+			case Renamed:
+				oldKey, newKey := renamedKeys(diff.Key)
+				timeline := get(oldKey)
+				timeline.Transitions = append(timeline.Transitions,
+					Transition{
+						Version: pair.NewVersion,
+						Event:   fmt.Sprintf("Renamed to `%s` in `%s`.", newKey, pair.NewVersion),
+					},
+					Transition{
+						Version: pair.NewVersion,
+						Event:   fmt.Sprintf("Renamed from `%s` in `%s`.", oldKey, pair.NewVersion),
+					},
+				)
+
+				// Fold the old key's history into the new key so the
+				// lifetime view shows one continuous section instead of
+				// splitting pre- and post-rename history in two.
+				delete(timelines, oldKey)
+				timeline.Key = newKey
+				timelines[newKey] = timeline
+			}
+		}
+	}
+
+	return timelines
+}
+
+// renamedKeys splits a Renamed diff's "old → new" key back into its two
+// halves.
+func renamedKeys(key string) (oldKey, newKey string) {
+	const sep = " → "
+	for i := 0; i+len(sep) <= len(key); i++ {
+		if key[i:i+len(sep)] == sep {
+			return key[:i], key[i+len(sep):]
+		}
+	}
+	return key, key
+}
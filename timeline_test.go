@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildTimelinesFoldsRenamedHistory(t *testing.T) {
+	snapshots := []Snapshot{
+		{
+			Version: "1.30",
+			Metrics: map[string]Metric{
+				"kube_old_name": {Name: "kube_old_name", Help: "Old help.", Type: "gauge", Labels: []string{"namespace"}},
+			},
+		},
+		{
+			Version: "1.31",
+			Metrics: map[string]Metric{
+				"kube_new_name": {Name: "kube_new_name", Help: "Old help.", Type: "gauge", Labels: []string{"namespace"}},
+			},
+		},
+		{
+			Version: "1.32",
+			Metrics: map[string]Metric{
+				"kube_new_name": {Name: "kube_new_name", Help: "New help.", Type: "gauge", Labels: []string{"namespace"}},
+			},
+		},
+	}
+	pairDiffs := walkVersions(snapshots)
+
+	timelines := buildTimelines(snapshots, pairDiffs)
+
+	if _, ok := timelines["kube_old_name"]; ok {
+		t.Errorf("timelines still has a separate entry for kube_old_name, want folded into kube_new_name")
+	}
+
+	timeline, ok := timelines["kube_new_name"]
+	if !ok {
+		t.Fatalf("timelines missing kube_new_name")
+	}
+	if timeline.Key != "kube_new_name" {
+		t.Errorf("timeline.Key = %q, want kube_new_name", timeline.Key)
+	}
+
+	want := []Transition{
+		{Version: "1.30", Event: "Present as of `1.30`."},
+		{Version: "1.31", Event: "Renamed to `kube_new_name` in `1.31`."},
+		{Version: "1.31", Event: "Renamed from `kube_old_name` in `1.31`."},
+		{Version: "1.32", Event: "Help text changed."},
+	}
+	if !reflect.DeepEqual(timeline.Transitions, want) {
+		t.Errorf("Transitions = %+v, want %+v", timeline.Transitions, want)
+	}
+}
+
+func TestBuildTimelinesAddedAndRemoved(t *testing.T) {
+	snapshots := []Snapshot{
+		{Version: "1.30", Metrics: map[string]Metric{}},
+		{
+			Version: "1.31",
+			Metrics: map[string]Metric{
+				"kube_new_metric": {Name: "kube_new_metric", Help: "New.", Type: "gauge"},
+			},
+		},
+		{Version: "1.32", Metrics: map[string]Metric{}},
+	}
+	pairDiffs := walkVersions(snapshots)
+
+	timelines := buildTimelines(snapshots, pairDiffs)
+
+	timeline, ok := timelines["kube_new_metric"]
+	if !ok {
+		t.Fatalf("timelines missing kube_new_metric")
+	}
+
+	want := []Transition{
+		{Version: "1.31", Event: "Introduced in `1.31`."},
+		{Version: "1.32", Event: "Removed in `1.32`."},
+	}
+	if !reflect.DeepEqual(timeline.Transitions, want) {
+		t.Errorf("Transitions = %+v, want %+v", timeline.Transitions, want)
+	}
+}
+
+func TestRenamedKeys(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantOld string
+		wantNew string
+	}{
+		{"kube_old_name → kube_new_name", "kube_old_name", "kube_new_name"},
+		{"no_separator_here", "no_separator_here", "no_separator_here"},
+	}
+
+	for _, tt := range tests {
+		oldKey, newKey := renamedKeys(tt.key)
+		if oldKey != tt.wantOld || newKey != tt.wantNew {
+			t.Errorf("renamedKeys(%q) = (%q, %q), want (%q, %q)", tt.key, oldKey, newKey, tt.wantOld, tt.wantNew)
+		}
+	}
+}